@@ -1,14 +1,21 @@
 /*
 	Package healthserver registers HTTP handlers for health and readiness checks
 
-	Package healthserver registers HTTP handlers for the /healthz and
-	/readiness paths. These handlers are always registered (this is done at
-	package init time).
+	Package healthserver registers HTTP handlers for the /healthz, /livez,
+	/readiness, and /startup paths, following Kubernetes probe conventions.
+	These handlers are always registered (this is done at package init
+	time).
 
-	By default, the /health handler responds with "OK".
+	By default, the /healthz and /livez handlers respond with "OK".
 
-	By default, the /readiness handler responds with a "503 Service Unavailable"
-	HTTP status and followed by "not ready".
+	By default, the /readiness handler responds with a "503 Service
+	Unavailable" HTTP status and a JSON body whose "legacy" entry has the
+	reason "not ready", until SetReady is called. Once ready, /readiness
+	additionally reports 503 with a JSON body while any gate registered via RegisterGate is not GateReady; each
+	gate is also available at its own "/readiness/<name>" sub-path. The
+	/startup handler reports success while readiness has not yet been
+	reached but the process is within its startup grace period (see
+	SetStartupGracePeriod), and failure thereafter.
 */
 package healthserver
 
@@ -0,0 +1,153 @@
+package healthserver
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Cloud-Foundations/tricorder/go/tricorder"
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/units"
+)
+
+// GateState is the current state of a readiness Gate.
+type GateState int
+
+const (
+	// GatePending means the gate has not yet reported ready or failed.
+	// Readiness reports it as "pending" and, like a GateFailed gate, as
+	// not ready.
+	GatePending GateState = iota
+	// GateReady means the gate's dependency is available.
+	GateReady
+	// GateFailed means the gate's dependency is known to be unavailable.
+	GateFailed
+)
+
+func (s GateState) String() string {
+	switch s {
+	case GateReady:
+		return "ready"
+	case GateFailed:
+		return "failed"
+	default:
+		return "pending"
+	}
+}
+
+// Gate is a named readiness dependency, e.g. a database connection or a
+// config reload. The overall /readiness endpoint reports 200 only when
+// every registered Gate is ready.
+type Gate interface {
+	// SetReady marks the gate ready.
+	SetReady()
+	// SetFailed marks the gate failed, recording reason for
+	// /readiness's JSON body.
+	SetFailed(reason string)
+	// SetPending reverts the gate to GatePending, e.g. while a
+	// dependency is being re-established.
+	SetPending()
+	// State returns the gate's current state and, if GateFailed, the
+	// reason passed to SetFailed.
+	State() (GateState, string)
+}
+
+// gate is the concrete Gate implementation, also used directly by the
+// /readiness handlers to read state and transition metadata.
+type gate struct {
+	name string
+
+	mu             sync.Mutex
+	state          GateState
+	reason         string
+	lastTransition time.Time
+}
+
+var (
+	gatesMutex sync.Mutex
+	gates      = make(map[string]*gate)
+)
+
+// RegisterGate registers a new named readiness gate, initially
+// GatePending, and returns a handle callers use to transition it.
+// RegisterGate also registers tricorder metrics at
+// "readiness/gates/<name>/state" and
+// "readiness/gates/<name>/lastTransitionTime" so probe flapping is
+// observable.
+//
+// RegisterGate panics if name is already registered, since that indicates
+// a programming error (e.g. two subsystems racing to own the same
+// dependency).
+func RegisterGate(name string) Gate {
+	gatesMutex.Lock()
+	defer gatesMutex.Unlock()
+	if _, ok := gates[name]; ok {
+		panic(fmt.Sprintf("healthserver: gate %q already registered", name))
+	}
+	g := &gate{name: name, lastTransition: time.Now()}
+	gates[name] = g
+	registerGateMetrics(g)
+	return g
+}
+
+func registerGateMetrics(g *gate) {
+	basePath := "readiness/gates/" + g.name
+	tricorder.RegisterMetric(
+		basePath+"/state",
+		func() string {
+			state, _ := g.State()
+			return state.String()
+		},
+		units.None,
+		"Current state of this readiness gate: pending, ready, or failed.")
+	tricorder.RegisterMetric(
+		basePath+"/lastTransitionTime",
+		func() time.Time {
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			return g.lastTransition
+		},
+		units.None,
+		"Time this readiness gate last changed state.")
+}
+
+func (g *gate) SetReady()               { g.transition(GateReady, "") }
+func (g *gate) SetFailed(reason string) { g.transition(GateFailed, reason) }
+func (g *gate) SetPending()             { g.transition(GatePending, "") }
+
+func (g *gate) transition(state GateState, reason string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.state == state && g.reason == reason {
+		return
+	}
+	g.state = state
+	g.reason = reason
+	g.lastTransition = time.Now()
+}
+
+func (g *gate) State() (GateState, string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.state, g.reason
+}
+
+// allGates returns a stable-ordered snapshot of every registered gate.
+// Exposed for the /readiness handlers.
+func allGates() []*gate {
+	gatesMutex.Lock()
+	defer gatesMutex.Unlock()
+	result := make([]*gate, 0, len(gates))
+	for _, g := range gates {
+		result = append(result, g)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].name < result[j].name })
+	return result
+}
+
+func findGate(name string) (*gate, bool) {
+	gatesMutex.Lock()
+	defer gatesMutex.Unlock()
+	g, ok := gates[name]
+	return g, ok
+}
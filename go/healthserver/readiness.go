@@ -0,0 +1,162 @@
+package healthserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	readyMu     sync.RWMutex
+	readyStatus = "not ready" // "" means ready, per SetReady/SetNotReady.
+
+	healthMu     sync.RWMutex
+	healthStatus string // "" means healthy, per SetHealthy/SetNotHealthy.
+
+	startupMu          sync.RWMutex
+	startTime          = time.Now()
+	startupGracePeriod = time.Minute
+)
+
+func setHealth(status string) {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	healthStatus = status
+}
+
+func setReady(status string) {
+	readyMu.Lock()
+	defer readyMu.Unlock()
+	readyStatus = status
+}
+
+// SetStartupGracePeriod overrides how long the /startup probe tolerates a
+// not-yet-ready process before it starts failing. Call it during process
+// initialization, before traffic arrives. The default is one minute.
+func SetStartupGracePeriod(d time.Duration) {
+	startupMu.Lock()
+	defer startupMu.Unlock()
+	startupGracePeriod = d
+}
+
+// gateStatus is the JSON representation of a single gate in a /readiness
+// response body.
+type gateStatus struct {
+	Name   string `json:"name"`
+	State  string `json:"state"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// readinessBody is the JSON body written by /readiness (and /startup) when
+// the process is not ready.
+type readinessBody struct {
+	Ready bool         `json:"ready"`
+	Gates []gateStatus `json:"gates,omitempty"`
+}
+
+// isLegacyReady reports whether SetReady/SetNotReady considers the process
+// ready, independent of any registered gates.
+func isLegacyReady() (bool, string) {
+	readyMu.RLock()
+	defer readyMu.RUnlock()
+	return readyStatus == "", readyStatus
+}
+
+// gatesSnapshot returns every registered gate's current status, and
+// whether all of them are ready.
+func gatesSnapshot() ([]gateStatus, bool) {
+	all := allGates()
+	statuses := make([]gateStatus, 0, len(all))
+	allReady := true
+	for _, g := range all {
+		state, reason := g.State()
+		if state != GateReady {
+			allReady = false
+		}
+		statuses = append(statuses, gateStatus{Name: g.name, State: state.String(), Reason: reason})
+	}
+	return statuses, allReady
+}
+
+func writeReadinessResponse(w http.ResponseWriter, ready bool, gates []gateStatus) {
+	if ready {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(readinessBody{Ready: false, Gates: gates})
+}
+
+func healthzHandler(w http.ResponseWriter, r *http.Request) {
+	healthMu.RLock()
+	status := healthStatus
+	healthMu.RUnlock()
+	if status == "" {
+		w.Write([]byte("OK"))
+		return
+	}
+	http.Error(w, status, http.StatusServiceUnavailable)
+}
+
+// livezHandler implements Kubernetes' /livez convention: it reports
+// whether the process itself should be restarted, which is independent of
+// whether it is ready to serve traffic. tricorder has no deadlock
+// detector, so /livez always succeeds; processes that want liveness
+// failures should call SetNotHealthy, which only affects /healthz.
+func livezHandler(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("OK"))
+}
+
+func readinessHandler(w http.ResponseWriter, r *http.Request) {
+	if name := strings.TrimPrefix(r.URL.Path, "/readiness/"); name != r.URL.Path && name != "" {
+		singleGateHandler(w, name)
+		return
+	}
+	legacyReady, legacyReason := isLegacyReady()
+	gates, gatesReady := gatesSnapshot()
+	ready := legacyReady && gatesReady
+	if !ready && legacyReason != "" {
+		gates = append(gates, gateStatus{Name: "legacy", State: "failed", Reason: legacyReason})
+	}
+	writeReadinessResponse(w, ready, gates)
+}
+
+func singleGateHandler(w http.ResponseWriter, name string) {
+	g, ok := findGate(name)
+	if !ok {
+		http.NotFound(w, nil)
+		return
+	}
+	state, reason := g.State()
+	writeReadinessResponse(w, state == GateReady, []gateStatus{{Name: name, State: state.String(), Reason: reason}})
+}
+
+func startupHandler(w http.ResponseWriter, r *http.Request) {
+	legacyReady, _ := isLegacyReady()
+	_, gatesReady := gatesSnapshot()
+	if legacyReady && gatesReady {
+		writeReadinessResponse(w, true, nil)
+		return
+	}
+	startupMu.RLock()
+	grace := startupGracePeriod
+	startupMu.RUnlock()
+	if time.Since(startTime) < grace {
+		writeReadinessResponse(w, true, nil)
+		return
+	}
+	gates, _ := gatesSnapshot()
+	writeReadinessResponse(w, false, gates)
+}
+
+func init() {
+	http.HandleFunc("/healthz", healthzHandler)
+	http.HandleFunc("/livez", livezHandler)
+	http.HandleFunc("/readiness", readinessHandler)
+	http.HandleFunc("/readiness/", readinessHandler)
+	http.HandleFunc("/startup", startupHandler)
+}
@@ -0,0 +1,45 @@
+// Package grpcserver implements the messagespb.MetricService gRPC service,
+// giving non-Go clients a schema-typed interface to a tricorder metrics
+// tree that mirrors the Go net/rpc MetricServer.
+package grpcserver
+
+import (
+	"time"
+
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/messages"
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/messagespb"
+)
+
+// MetricSource is the subset of a tricorder metrics registry the server
+// needs: listing metrics (optionally restricted to a subtree) and looking
+// up a single metric by path.
+type MetricSource interface {
+	// ListMetrics returns every metric whose path is at or below prefix.
+	// An empty prefix returns every metric.
+	ListMetrics(prefix string) (messages.MetricList, error)
+	// GetMetric returns the metric at path, or
+	// messages.ErrMetricNotFound if none exists.
+	GetMetric(path string) (*messages.Metric, error)
+}
+
+// Server implements messagespb.MetricServiceServer over a MetricSource.
+type Server struct {
+	messagespb.UnimplementedMetricServiceServer
+
+	source MetricSource
+	// pollInterval is how often WatchMetrics re-reads a watched metric
+	// to notice changes. Defaults to 1 second.
+	pollInterval time.Duration
+}
+
+// New creates a Server backed by source.
+func New(source MetricSource) *Server {
+	return &Server{source: source, pollInterval: time.Second}
+}
+
+// SetPollInterval overrides the default change-detection poll interval
+// used by WatchMetrics. It must be called before the server starts
+// serving requests.
+func (s *Server) SetPollInterval(d time.Duration) {
+	s.pollInterval = d
+}
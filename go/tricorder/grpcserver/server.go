@@ -0,0 +1,92 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/messages"
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/messagespb"
+)
+
+// ListMetrics implements messagespb.MetricServiceServer.
+func (s *Server) ListMetrics(ctx context.Context, req *messagespb.ListMetricsRequest) (
+	*messagespb.ListMetricsResponse, error) {
+	list, err := s.source.ListMetrics(req.Path)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "listing metrics: %v", err)
+	}
+	pb, err := messagespb.MetricListToProto(list)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "converting metrics: %v", err)
+	}
+	return &messagespb.ListMetricsResponse{Metrics: pb}, nil
+}
+
+// GetMetric implements messagespb.MetricServiceServer.
+func (s *Server) GetMetric(ctx context.Context, req *messagespb.GetMetricRequest) (
+	*messagespb.GetMetricResponse, error) {
+	m, err := s.source.GetMetric(req.Path)
+	if errors.Is(err, messages.ErrMetricNotFound) {
+		return nil, status.Errorf(codes.NotFound, "no metric at %q", req.Path)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "getting metric: %v", err)
+	}
+	pb, err := messagespb.MetricToProto(m)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "converting metric: %v", err)
+	}
+	return &messagespb.GetMetricResponse{Metric: pb}, nil
+}
+
+// WatchMetrics implements messagespb.MetricServiceServer. It polls every
+// metric under req.Path at s.pollInterval and pushes a
+// WatchMetricsResponse each time a metric changes: for distributions,
+// when Generation changes; for everything else, when the value itself
+// changes.
+func (s *Server) WatchMetrics(req *messagespb.WatchMetricsRequest, stream messagespb.MetricService_WatchMetricsServer) error {
+	ctx := stream.Context()
+	lastChangeKey := make(map[string]string)
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			list, err := s.source.ListMetrics(req.Path)
+			if err != nil {
+				return status.Errorf(codes.Internal, "listing metrics: %v", err)
+			}
+			for _, m := range list {
+				key := changeKeyOf(m)
+				if prev, ok := lastChangeKey[m.Path]; ok && prev == key {
+					continue
+				}
+				lastChangeKey[m.Path] = key
+				pb, err := messagespb.MetricToProto(m)
+				if err != nil {
+					return status.Errorf(codes.Internal, "converting metric: %v", err)
+				}
+				if err := stream.Send(&messagespb.WatchMetricsResponse{Metric: pb}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// changeKeyOf returns a string that changes exactly when m's reported
+// value would: a distribution's Generation, or a scalar/list's value
+// formatted for comparison.
+func changeKeyOf(m *messages.Metric) string {
+	if dist, ok := m.Value.(*messages.Distribution); ok {
+		return fmt.Sprintf("generation:%d", dist.Generation)
+	}
+	return fmt.Sprintf("value:%v", m.Value)
+}
@@ -50,6 +50,12 @@ type Distribution struct {
 	IsNotCumulative bool `json:"isNotCumulative,omitempty"`
 	// The number of values within each range
 	Ranges []*RangeWithCount `json:"ranges,omitempty"`
+	// Exponential holds this distribution's histogram using
+	// base^scale exponential buckets instead of explicit Ranges. At
+	// most one of Exponential and Ranges is populated; callers that
+	// only understand explicit Ranges can convert one to the other
+	// with ExponentialBuckets.ToExplicitRanges.
+	Exponential *ExponentialBuckets `json:"exponential,omitempty"`
 }
 
 func (d *Distribution) Type() types.Type {
@@ -0,0 +1,119 @@
+package messages
+
+import "fmt"
+
+// Merge combines other into d in place: Min, Max, Sum, and Count are
+// combined, each bucket in Ranges has other's corresponding bucket's Count
+// added to it, and Generation is incremented. d and other must have
+// identical bucket bounds (same length, same Lower/Upper per bucket);
+// Merge is intended for combining distributions of the same metric
+// collected from different processes, which always share a bucket
+// schema. Merge returns an error, leaving d unchanged, if the bucket
+// bounds don't match.
+func (d *Distribution) Merge(other *Distribution) error {
+	if err := checkSameBuckets(d, other); err != nil {
+		return fmt.Errorf("messages: Merge: %w", err)
+	}
+	if other.Count == 0 {
+		d.Generation++
+		return nil
+	}
+	if d.Count == 0 {
+		d.Min = other.Min
+		d.Max = other.Max
+	} else {
+		if other.Min < d.Min {
+			d.Min = other.Min
+		}
+		if other.Max > d.Max {
+			d.Max = other.Max
+		}
+	}
+	d.Sum += other.Sum
+	d.Count += other.Count
+	for i, r := range other.Ranges {
+		d.Ranges[i].Count += r.Count
+	}
+	d.Average = d.Sum / float64(d.Count)
+	d.Median = approximateMedian(d.Ranges, d.Count)
+	d.Generation++
+	return nil
+}
+
+// Subtract returns a new, non-cumulative Distribution representing the
+// values added to d since prev was captured: Sum, Count, and each bucket's
+// Count are d's minus prev's. d and prev must have identical bucket
+// bounds, and d must be a superset of prev (every field of d must be >=
+// the corresponding field of prev), as is always true when prev and d are
+// two samples of the same growing, cumulative distribution. This is how
+// push-based exporters (OTLP, Stackdriver) convert tricorder's cumulative
+// distributions into the deltas their wire formats expect.
+func (d *Distribution) Subtract(prev *Distribution) (*Distribution, error) {
+	if err := checkSameBuckets(d, prev); err != nil {
+		return nil, fmt.Errorf("messages: Subtract: %w", err)
+	}
+	if d.Count < prev.Count {
+		return nil, fmt.Errorf("messages: Subtract: d.Count (%d) < prev.Count (%d): not a later sample of the same cumulative distribution", d.Count, prev.Count)
+	}
+	ranges := make([]*RangeWithCount, len(d.Ranges))
+	for i, r := range d.Ranges {
+		count := r.Count - prev.Ranges[i].Count
+		if count > r.Count {
+			return nil, fmt.Errorf("messages: Subtract: bucket %d count decreased", i)
+		}
+		ranges[i] = &RangeWithCount{Lower: r.Lower, Upper: r.Upper, Count: count}
+	}
+	count := d.Count - prev.Count
+	sum := d.Sum - prev.Sum
+	delta := &Distribution{
+		Min:             d.Min,
+		Max:             d.Max,
+		Sum:             sum,
+		Count:           count,
+		Generation:      1,
+		IsNotCumulative: true,
+		Ranges:          ranges,
+	}
+	if count > 0 {
+		delta.Average = sum / float64(count)
+		delta.Median = approximateMedian(ranges, count)
+	}
+	return delta, nil
+}
+
+// checkSameBuckets reports an error unless a and b have the same number of
+// Ranges with identical Lower/Upper bounds in the same order.
+func checkSameBuckets(a, b *Distribution) error {
+	if len(a.Ranges) != len(b.Ranges) {
+		return fmt.Errorf("bucket count mismatch: %d vs %d", len(a.Ranges), len(b.Ranges))
+	}
+	for i, r := range a.Ranges {
+		other := b.Ranges[i]
+		if r.Lower != other.Lower || r.Upper != other.Upper {
+			return fmt.Errorf("bucket %d bounds mismatch: [%g, %g) vs [%g, %g)",
+				i, r.Lower, r.Upper, other.Lower, other.Upper)
+		}
+	}
+	return nil
+}
+
+// approximateMedian estimates the median from bucketed counts as the Upper
+// bound of whichever bucket contains the middle value. It returns 0 if
+// count is 0.
+func approximateMedian(ranges []*RangeWithCount, count uint64) float64 {
+	if count == 0 {
+		return 0
+	}
+	target := (count + 1) / 2
+	var cumulative uint64
+	for _, r := range ranges {
+		cumulative += r.Count
+		if cumulative >= target {
+			return r.Upper
+		}
+	}
+	if len(ranges) > 0 {
+		return ranges[len(ranges)-1].Upper
+	}
+	return 0
+}
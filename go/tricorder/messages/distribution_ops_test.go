@@ -0,0 +1,289 @@
+package messages
+
+import (
+	"testing"
+)
+
+func rangesFromCounts(bounds []float64, counts []uint64) []*RangeWithCount {
+	ranges := make([]*RangeWithCount, len(counts))
+	var lower float64
+	for i, count := range counts {
+		upper := 0.0
+		if i < len(bounds) {
+			upper = bounds[i]
+		}
+		ranges[i] = &RangeWithCount{Lower: lower, Upper: upper, Count: count}
+		lower = upper
+	}
+	return ranges
+}
+
+func sumRangeCounts(ranges []*RangeWithCount) uint64 {
+	var total uint64
+	for _, r := range ranges {
+		total += r.Count
+	}
+	return total
+}
+
+func TestDistributionMerge(t *testing.T) {
+	bounds := []float64{1, 2}
+	tests := []struct {
+		name       string
+		d          *Distribution
+		other      *Distribution
+		wantErr    bool
+		wantCount  uint64
+		wantSum    float64
+		wantMin    float64
+		wantMax    float64
+		wantBucket []uint64
+	}{
+		{
+			name: "disjoint bucket bounds rejected",
+			d: &Distribution{
+				Ranges: rangesFromCounts(bounds, []uint64{1, 1, 1}),
+			},
+			other: &Distribution{
+				Ranges: rangesFromCounts([]float64{5, 6}, []uint64{1, 1, 1}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "different bucket count rejected",
+			d: &Distribution{
+				Ranges: rangesFromCounts(bounds, []uint64{1, 1, 1}),
+			},
+			other: &Distribution{
+				Ranges: rangesFromCounts(bounds, []uint64{1, 1}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty other leaves d's counts, sum and bounds unchanged",
+			d: &Distribution{
+				Min: 1, Max: 9, Sum: 10, Count: 2,
+				Ranges: rangesFromCounts(bounds, []uint64{1, 1, 0}),
+			},
+			other: &Distribution{
+				Ranges: rangesFromCounts(bounds, []uint64{0, 0, 0}),
+			},
+			wantCount:  2,
+			wantSum:    10,
+			wantMin:    1,
+			wantMax:    9,
+			wantBucket: []uint64{1, 1, 0},
+		},
+		{
+			name: "merging into an empty distribution adopts other's Min/Max",
+			d: &Distribution{
+				Ranges: rangesFromCounts(bounds, []uint64{0, 0, 0}),
+			},
+			other: &Distribution{
+				Min: 2, Max: 8, Sum: 10, Count: 2,
+				Ranges: rangesFromCounts(bounds, []uint64{1, 0, 1}),
+			},
+			wantCount:  2,
+			wantSum:    10,
+			wantMin:    2,
+			wantMax:    8,
+			wantBucket: []uint64{1, 0, 1},
+		},
+		{
+			name: "combining two populated distributions",
+			d: &Distribution{
+				Min: 0, Max: 5, Sum: 5, Count: 2,
+				Ranges: rangesFromCounts(bounds, []uint64{2, 0, 0}),
+			},
+			other: &Distribution{
+				Min: -3, Max: 9, Sum: 6, Count: 3,
+				Ranges: rangesFromCounts(bounds, []uint64{0, 1, 2}),
+			},
+			wantCount:  5,
+			wantSum:    11,
+			wantMin:    -3,
+			wantMax:    9,
+			wantBucket: []uint64{2, 1, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generationBefore := tt.d.Generation
+			err := tt.d.Merge(tt.other)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Merge: expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Merge: unexpected error: %v", err)
+			}
+			if tt.d.Count != tt.wantCount {
+				t.Errorf("Count = %d, want %d", tt.d.Count, tt.wantCount)
+			}
+			if tt.d.Sum != tt.wantSum {
+				t.Errorf("Sum = %g, want %g", tt.d.Sum, tt.wantSum)
+			}
+			if tt.d.Min != tt.wantMin {
+				t.Errorf("Min = %g, want %g", tt.d.Min, tt.wantMin)
+			}
+			if tt.d.Max != tt.wantMax {
+				t.Errorf("Max = %g, want %g", tt.d.Max, tt.wantMax)
+			}
+			for i, want := range tt.wantBucket {
+				if got := tt.d.Ranges[i].Count; got != want {
+					t.Errorf("Ranges[%d].Count = %d, want %d", i, got, want)
+				}
+			}
+			if tt.d.Generation != generationBefore+1 {
+				t.Errorf("Generation = %d, want %d", tt.d.Generation, generationBefore+1)
+			}
+		})
+	}
+}
+
+func TestDistributionSubtract(t *testing.T) {
+	bounds := []float64{1, 2}
+	tests := []struct {
+		name              string
+		d                 *Distribution
+		prev              *Distribution
+		wantErr           bool
+		wantCount         uint64
+		wantSum           float64
+		wantBucket        []uint64
+		wantNotCumulative bool
+	}{
+		{
+			name: "mismatched bucket bounds rejected",
+			d: &Distribution{
+				Ranges: rangesFromCounts(bounds, []uint64{1, 1, 1}),
+			},
+			prev: &Distribution{
+				Ranges: rangesFromCounts([]float64{5, 6}, []uint64{1, 1, 1}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "prev count greater than d count rejected",
+			d: &Distribution{
+				Count:  1,
+				Ranges: rangesFromCounts(bounds, []uint64{1, 0, 0}),
+			},
+			prev: &Distribution{
+				Count:  5,
+				Ranges: rangesFromCounts(bounds, []uint64{5, 0, 0}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "per-bucket underflow rejected even when total count doesn't underflow",
+			d: &Distribution{
+				Count:  3,
+				Ranges: rangesFromCounts(bounds, []uint64{1, 2, 0}),
+			},
+			prev: &Distribution{
+				Count:  2,
+				Ranges: rangesFromCounts(bounds, []uint64{2, 0, 0}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "equal snapshots produce an empty, non-cumulative delta",
+			d: &Distribution{
+				Sum: 10, Count: 2,
+				Ranges: rangesFromCounts(bounds, []uint64{1, 1, 0}),
+			},
+			prev: &Distribution{
+				Sum: 10, Count: 2,
+				Ranges: rangesFromCounts(bounds, []uint64{1, 1, 0}),
+			},
+			wantCount:         0,
+			wantSum:           0,
+			wantBucket:        []uint64{0, 0, 0},
+			wantNotCumulative: true,
+		},
+		{
+			name: "later cumulative sample yields the delta added since prev",
+			d: &Distribution{
+				Sum: 20, Count: 5,
+				Ranges: rangesFromCounts(bounds, []uint64{2, 2, 1}),
+			},
+			prev: &Distribution{
+				Sum: 10, Count: 2,
+				Ranges: rangesFromCounts(bounds, []uint64{1, 1, 0}),
+			},
+			wantCount:         3,
+			wantSum:           10,
+			wantBucket:        []uint64{1, 1, 1},
+			wantNotCumulative: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delta, err := tt.d.Subtract(tt.prev)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Subtract: expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Subtract: unexpected error: %v", err)
+			}
+			if delta.Count != tt.wantCount {
+				t.Errorf("Count = %d, want %d", delta.Count, tt.wantCount)
+			}
+			if delta.Sum != tt.wantSum {
+				t.Errorf("Sum = %g, want %g", delta.Sum, tt.wantSum)
+			}
+			if delta.IsNotCumulative != tt.wantNotCumulative {
+				t.Errorf("IsNotCumulative = %v, want %v", delta.IsNotCumulative, tt.wantNotCumulative)
+			}
+			for i, want := range tt.wantBucket {
+				if got := delta.Ranges[i].Count; got != want {
+					t.Errorf("Ranges[%d].Count = %d, want %d", i, got, want)
+				}
+			}
+			if sumRangeCounts(delta.Ranges) != delta.Count {
+				t.Errorf("bucket counts sum to %d, want %d", sumRangeCounts(delta.Ranges), delta.Count)
+			}
+		})
+	}
+}
+
+func TestApproximateMedian(t *testing.T) {
+	tests := []struct {
+		name   string
+		ranges []*RangeWithCount
+		count  uint64
+		want   float64
+	}{
+		{
+			name:   "empty distribution",
+			ranges: nil,
+			count:  0,
+			want:   0,
+		},
+		{
+			name:   "middle value falls in the first bucket",
+			ranges: rangesFromCounts([]float64{1, 2}, []uint64{10, 0, 0}),
+			count:  10,
+			want:   1,
+		},
+		{
+			name:   "middle value falls in a later finite bucket",
+			ranges: rangesFromCounts([]float64{1, 2}, []uint64{1, 8, 1}),
+			count:  10,
+			want:   2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := approximateMedian(tt.ranges, tt.count); got != tt.want {
+				t.Errorf("approximateMedian() = %g, want %g", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package messages
+
+import "math"
+
+// ExponentialBuckets represents a distribution's histogram using base^scale
+// exponential bucket boundaries, as in OpenTelemetry's exponential
+// histograms, rather than the explicit per-bucket bounds in Distribution's
+// Ranges field. Exponential buckets let a distribution's resolution be
+// tuned (via Scale) without needing to predeclare every bucket boundary up
+// front. Distribution.Merge and Distribution.Subtract combine Ranges only;
+// they do not combine Exponential buckets.
+//
+// Bucket i, for i in [1, len(PositiveCounts)], covers the value range
+// (base^(PositiveOffset+i-1), base^(PositiveOffset+i)], where
+// base = 2^(2^-Scale). NegativeCounts mirrors PositiveCounts for negative
+// values using the same base. ZeroCount holds the number of exactly-zero
+// values, which exponential bucketing otherwise cannot represent.
+type ExponentialBuckets struct {
+	// Scale controls the resolution: higher Scale means narrower,
+	// more numerous buckets for the same value range.
+	Scale int32 `json:"scale"`
+	// ZeroCount is the number of values that were exactly zero.
+	ZeroCount uint64 `json:"zeroCount"`
+	// PositiveOffset is the index of the first positive bucket: bucket
+	// PositiveCounts[0] covers (base^PositiveOffset, base^(PositiveOffset+1)].
+	PositiveOffset int32 `json:"positiveOffset"`
+	// PositiveCounts holds the per-bucket counts for positive values.
+	PositiveCounts []uint64 `json:"positiveCounts,omitempty"`
+	// NegativeOffset is the negative-side equivalent of PositiveOffset.
+	NegativeOffset int32 `json:"negativeOffset"`
+	// NegativeCounts holds the per-bucket counts for negative values.
+	NegativeCounts []uint64 `json:"negativeCounts,omitempty"`
+}
+
+// Base returns the exponential growth factor between consecutive bucket
+// boundaries for the given scale: base = 2^(2^-scale).
+func Base(scale int32) float64 {
+	return math.Pow(2, math.Pow(2, -float64(scale)))
+}
+
+// UpperBound returns the upper boundary of the bucket at index, where
+// index is relative to PositiveOffset (or NegativeOffset, for negative
+// buckets): base^(offset+index+1).
+func (e *ExponentialBuckets) UpperBound(offset, index int32) float64 {
+	return math.Pow(Base(e.Scale), float64(offset+index+1))
+}
+
+// ToExplicitRanges re-buckets e into the explicit, arbitrary bounds given
+// by sortedBounds (which must be sorted ascending), returning
+// RangeWithCount values suitable for Distribution.Ranges. This lets
+// exponential-bucket distributions be reported to clients that only
+// understand tricorder's original explicit-bounds JSON format.
+//
+// Each exponential bucket's count is assigned to the explicit bucket that
+// contains the exponential bucket's upper boundary. Explicit ranges
+// exclude their Upper bound, so an exponential bucket whose upper boundary
+// equals an explicit bound lands in the explicit range above that bound;
+// the result is an approximation, same as any histogram re-bucketing.
+func (e *ExponentialBuckets) ToExplicitRanges(sortedBounds []float64) []*RangeWithCount {
+	counts := make([]uint64, len(sortedBounds)+1)
+	assign := func(value float64, count uint64) {
+		counts[explicitBucketIndex(sortedBounds, value)] += count
+	}
+	if e.ZeroCount > 0 {
+		assign(0, e.ZeroCount)
+	}
+	for i, count := range e.PositiveCounts {
+		if count > 0 {
+			assign(e.UpperBound(e.PositiveOffset, int32(i)), count)
+		}
+	}
+	for i, count := range e.NegativeCounts {
+		if count > 0 {
+			assign(-e.UpperBound(e.NegativeOffset, int32(i)), count)
+		}
+	}
+	ranges := make([]*RangeWithCount, len(sortedBounds)+1)
+	var lower float64
+	for i := range ranges {
+		upper := math.Inf(1)
+		if i < len(sortedBounds) {
+			upper = sortedBounds[i]
+		}
+		ranges[i] = &RangeWithCount{Lower: lower, Upper: upper, Count: counts[i]}
+		lower = upper
+	}
+	return ranges
+}
+
+// explicitBucketIndex returns the index of the first bucket in
+// sortedBounds whose upper bound is > value, or len(sortedBounds) if value
+// exceeds every bound.
+func explicitBucketIndex(sortedBounds []float64, value float64) int {
+	lo, hi := 0, len(sortedBounds)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if sortedBounds[mid] > value {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo
+}
@@ -0,0 +1,98 @@
+package messages
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBase(t *testing.T) {
+	tests := []struct {
+		scale int32
+		want  float64
+	}{
+		{scale: 0, want: 2},
+		{scale: 1, want: math.Sqrt2},
+		{scale: -1, want: 4},
+	}
+	for _, tt := range tests {
+		if got := Base(tt.scale); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Base(%d) = %g, want %g", tt.scale, got, tt.want)
+		}
+	}
+}
+
+func TestExponentialBucketsUpperBound(t *testing.T) {
+	e := &ExponentialBuckets{Scale: 0}
+	tests := []struct {
+		offset, index int32
+		want          float64
+	}{
+		{offset: 0, index: 0, want: 2},
+		{offset: 0, index: 1, want: 4},
+		{offset: 1, index: 0, want: 4},
+	}
+	for _, tt := range tests {
+		if got := e.UpperBound(tt.offset, tt.index); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("UpperBound(%d, %d) = %g, want %g", tt.offset, tt.index, got, tt.want)
+		}
+	}
+}
+
+func TestExponentialBucketsToExplicitRanges(t *testing.T) {
+	// Scale 0 gives base 2, so positive bucket i (offset 0) has upper
+	// bound 2^(i+1): bucket 0 is (1, 2], bucket 1 is (2, 4]. The mirrored
+	// negative bucket 0 has upper bound -2.
+	e := &ExponentialBuckets{
+		Scale:          0,
+		ZeroCount:      5,
+		PositiveOffset: 0,
+		PositiveCounts: []uint64{3, 7},
+		NegativeOffset: 0,
+		NegativeCounts: []uint64{2},
+	}
+	sortedBounds := []float64{2, 4}
+
+	ranges := e.ToExplicitRanges(sortedBounds)
+
+	if len(ranges) != len(sortedBounds)+1 {
+		t.Fatalf("len(ranges) = %d, want %d", len(ranges), len(sortedBounds)+1)
+	}
+
+	// The negative bucket's value (-2) and ZeroCount's value (0) both
+	// land at or below the first explicit bound of 2.
+	if got := ranges[0].Count; got != 7 {
+		t.Errorf("ranges[0] (<2) Count = %d, want 7", got)
+	}
+	// Positive bucket 0's upper bound (2) is not > the explicit bound of
+	// 2, so it lands in the [2,4) bucket alongside nothing else here.
+	if got := ranges[1].Count; got != 3 {
+		t.Errorf("ranges[1] [2,4) Count = %d, want 3", got)
+	}
+	// Positive bucket 1's upper bound (4) is not > the highest explicit
+	// bound either, landing in the overflow bucket by the same rule.
+	if got := ranges[2].Count; got != 7 {
+		t.Errorf("ranges[2] [4,+Inf) Count = %d, want 7", got)
+	}
+	if !math.IsInf(ranges[len(ranges)-1].Upper, 1) {
+		t.Errorf("last range Upper = %g, want +Inf", ranges[len(ranges)-1].Upper)
+	}
+}
+
+func TestExplicitBucketIndex(t *testing.T) {
+	bounds := []float64{1, 2, 4}
+	tests := []struct {
+		value float64
+		want  int
+	}{
+		{value: 0, want: 0},
+		{value: 1, want: 1},
+		{value: 1.5, want: 1},
+		{value: 4, want: 3},
+		{value: 5, want: 3},
+	}
+	for _, tt := range tests {
+		if got := explicitBucketIndex(bounds, tt.value); got != tt.want {
+			t.Errorf("explicitBucketIndex(%v, %g) = %d, want %d", bounds, tt.value, got, tt.want)
+		}
+	}
+}
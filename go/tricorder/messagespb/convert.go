@@ -0,0 +1,326 @@
+package messagespb
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/messages"
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/types"
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/units"
+)
+
+// kindToProto and its inverse map between the gob-registered types.Type
+// enum and the generated Type enum. They are built once from an explicit
+// table rather than relying on matching iota order between the two enums.
+var kindToProto = map[types.Type]Type{
+	types.Unknown:  Type_UNKNOWN,
+	types.Bool:     Type_BOOL,
+	types.Int8:     Type_INT8,
+	types.Int16:    Type_INT16,
+	types.Int32:    Type_INT32,
+	types.Int64:    Type_INT64,
+	types.Uint8:    Type_UINT8,
+	types.Uint16:   Type_UINT16,
+	types.Uint32:   Type_UINT32,
+	types.Uint64:   Type_UINT64,
+	types.Float32:  Type_FLOAT32,
+	types.Float64:  Type_FLOAT64,
+	types.String:   Type_STRING,
+	types.Time:     Type_TIME,
+	types.Duration: Type_DURATION,
+	types.Dist:     Type_DIST,
+	types.List:     Type_LIST,
+}
+
+var kindFromProto = inverseKindMap()
+
+func inverseKindMap() map[Type]types.Type {
+	m := make(map[Type]types.Type, len(kindToProto))
+	for k, v := range kindToProto {
+		m[v] = k
+	}
+	return m
+}
+
+// MetricToProto converts a messages.Metric to its protobuf equivalent.
+func MetricToProto(m *messages.Metric) (*Metric, error) {
+	out := &Metric{
+		Path:        m.Path,
+		Description: m.Description,
+		Unit:        unitToProto(m.Unit),
+		Kind:        kindToProto[m.Kind],
+		SubType:     kindToProto[m.SubType],
+		Bits:        int32(m.Bits),
+		GroupId:     int32(m.GroupId),
+		Timestamp:   timestampToProto(m.TimeStamp),
+	}
+	switch m.Kind {
+	case types.Dist:
+		dist, ok := m.Value.(*messages.Distribution)
+		if !ok {
+			return nil, fmt.Errorf("messagespb: %s: expected *messages.Distribution, got %T", m.Path, m.Value)
+		}
+		out.Value = &Metric_DistributionValue{DistributionValue: distributionToProto(dist)}
+	case types.List:
+		list, err := listToProto(m.Value)
+		if err != nil {
+			return nil, fmt.Errorf("messagespb: %s: %w", m.Path, err)
+		}
+		out.Value = &Metric_ListValue{ListValue: list}
+	default:
+		scalar, err := scalarToProto(m.Value)
+		if err != nil {
+			return nil, fmt.Errorf("messagespb: %s: %w", m.Path, err)
+		}
+		out.Value = &Metric_ScalarValue{ScalarValue: scalar}
+	}
+	return out, nil
+}
+
+// MetricFromProto converts a protobuf Metric back to a messages.Metric.
+func MetricFromProto(pb *Metric) (*messages.Metric, error) {
+	out := &messages.Metric{
+		Path:        pb.Path,
+		Description: pb.Description,
+		Unit:        unitFromProto(pb.Unit),
+		Kind:        kindFromProto[pb.Kind],
+		SubType:     kindFromProto[pb.SubType],
+		Bits:        int(pb.Bits),
+		GroupId:     int(pb.GroupId),
+		TimeStamp:   timestampFromProto(pb.Timestamp),
+	}
+	switch v := pb.Value.(type) {
+	case *Metric_DistributionValue:
+		out.Value = distributionFromProto(v.DistributionValue)
+	case *Metric_ListValue:
+		values, err := listFromProto(v.ListValue, out.SubType)
+		if err != nil {
+			return nil, fmt.Errorf("messagespb: %s: %w", pb.Path, err)
+		}
+		out.Value = values
+	case *Metric_ScalarValue:
+		value, err := scalarFromProto(v.ScalarValue, out.Kind)
+		if err != nil {
+			return nil, fmt.Errorf("messagespb: %s: %w", pb.Path, err)
+		}
+		out.Value = value
+	default:
+		return nil, fmt.Errorf("messagespb: %s: no value set", pb.Path)
+	}
+	return out, nil
+}
+
+// MetricListToProto converts a messages.MetricList to its protobuf
+// equivalent.
+func MetricListToProto(list messages.MetricList) (*MetricList, error) {
+	out := &MetricList{Metrics: make([]*Metric, 0, len(list))}
+	for _, m := range list {
+		pb, err := MetricToProto(m)
+		if err != nil {
+			return nil, err
+		}
+		out.Metrics = append(out.Metrics, pb)
+	}
+	return out, nil
+}
+
+func distributionToProto(d *messages.Distribution) *Distribution {
+	ranges := make([]*RangeWithCount, len(d.Ranges))
+	for i, r := range d.Ranges {
+		ranges[i] = &RangeWithCount{Lower: r.Lower, Upper: r.Upper, Count: r.Count}
+	}
+	return &Distribution{
+		Min:             d.Min,
+		Max:             d.Max,
+		Average:         d.Average,
+		Median:          d.Median,
+		Sum:             d.Sum,
+		Count:           d.Count,
+		Generation:      d.Generation,
+		IsNotCumulative: d.IsNotCumulative,
+		Ranges:          ranges,
+	}
+}
+
+func distributionFromProto(pb *Distribution) *messages.Distribution {
+	ranges := make([]*messages.RangeWithCount, len(pb.Ranges))
+	for i, r := range pb.Ranges {
+		ranges[i] = &messages.RangeWithCount{Lower: r.Lower, Upper: r.Upper, Count: r.Count}
+	}
+	return &messages.Distribution{
+		Min:             pb.Min,
+		Max:             pb.Max,
+		Average:         pb.Average,
+		Median:          pb.Median,
+		Sum:             pb.Sum,
+		Count:           pb.Count,
+		Generation:      pb.Generation,
+		IsNotCumulative: pb.IsNotCumulative,
+		Ranges:          ranges,
+	}
+}
+
+func scalarToProto(value interface{}) (*ScalarValue, error) {
+	switch v := value.(type) {
+	case bool:
+		return &ScalarValue{Value: &ScalarValue_BoolValue{BoolValue: v}}, nil
+	case int8:
+		return &ScalarValue{Value: &ScalarValue_IntValue{IntValue: int64(v)}}, nil
+	case int16:
+		return &ScalarValue{Value: &ScalarValue_IntValue{IntValue: int64(v)}}, nil
+	case int32:
+		return &ScalarValue{Value: &ScalarValue_IntValue{IntValue: int64(v)}}, nil
+	case int64:
+		return &ScalarValue{Value: &ScalarValue_IntValue{IntValue: v}}, nil
+	case uint8:
+		return &ScalarValue{Value: &ScalarValue_UintValue{UintValue: uint64(v)}}, nil
+	case uint16:
+		return &ScalarValue{Value: &ScalarValue_UintValue{UintValue: uint64(v)}}, nil
+	case uint32:
+		return &ScalarValue{Value: &ScalarValue_UintValue{UintValue: uint64(v)}}, nil
+	case uint64:
+		return &ScalarValue{Value: &ScalarValue_UintValue{UintValue: v}}, nil
+	case float32:
+		return &ScalarValue{Value: &ScalarValue_FloatValue{FloatValue: float64(v)}}, nil
+	case float64:
+		return &ScalarValue{Value: &ScalarValue_FloatValue{FloatValue: v}}, nil
+	case string:
+		return &ScalarValue{Value: &ScalarValue_StringValue{StringValue: v}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+func scalarFromProto(pb *ScalarValue, kind types.Type) (interface{}, error) {
+	switch v := pb.Value.(type) {
+	case *ScalarValue_BoolValue:
+		return v.BoolValue, nil
+	case *ScalarValue_IntValue:
+		return reshapeInt(v.IntValue, kind)
+	case *ScalarValue_UintValue:
+		return reshapeUint(v.UintValue, kind)
+	case *ScalarValue_FloatValue:
+		if kind == types.Float32 {
+			return float32(v.FloatValue), nil
+		}
+		return v.FloatValue, nil
+	case *ScalarValue_StringValue:
+		return v.StringValue, nil
+	default:
+		return nil, fmt.Errorf("no value set")
+	}
+}
+
+func reshapeInt(v int64, kind types.Type) (interface{}, error) {
+	switch kind {
+	case types.Int8:
+		return int8(v), nil
+	case types.Int16:
+		return int16(v), nil
+	case types.Int32:
+		return int32(v), nil
+	case types.Int64:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unexpected kind %v for int value", kind)
+	}
+}
+
+func reshapeUint(v uint64, kind types.Type) (interface{}, error) {
+	switch kind {
+	case types.Uint8:
+		return uint8(v), nil
+	case types.Uint16:
+		return uint16(v), nil
+	case types.Uint32:
+		return uint32(v), nil
+	case types.Uint64:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unexpected kind %v for uint value", kind)
+	}
+}
+
+func listToProto(value interface{}) (*ListValue, error) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("unsupported list type %T", value)
+	}
+	out := &ListValue{Values: make([]*ScalarValue, rv.Len())}
+	for i := 0; i < rv.Len(); i++ {
+		v, err := scalarToProto(rv.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		out.Values[i] = v
+	}
+	return out, nil
+}
+
+func listFromProto(pb *ListValue, subType types.Type) (interface{}, error) {
+	values := make([]interface{}, len(pb.Values))
+	for i, v := range pb.Values {
+		elem, err := scalarFromProto(v, subType)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		values[i] = elem
+	}
+	return values, nil
+}
+
+func unitToProto(u units.Unit) Unit {
+	switch u {
+	case units.Millisecond:
+		return Unit_UNIT_MILLISECOND
+	case units.Second:
+		return Unit_UNIT_SECOND
+	case units.Byte:
+		return Unit_UNIT_BYTE
+	case units.Celsius:
+		return Unit_UNIT_CELSIUS
+	case units.PercentBandwidth:
+		return Unit_UNIT_PERCENT_BANDWIDTH
+	case units.Bit16th:
+		return Unit_UNIT_16THS_OF_IEC_BYTE
+	default:
+		return Unit_UNIT_NONE
+	}
+}
+
+func unitFromProto(u Unit) units.Unit {
+	switch u {
+	case Unit_UNIT_MILLISECOND:
+		return units.Millisecond
+	case Unit_UNIT_SECOND:
+		return units.Second
+	case Unit_UNIT_BYTE:
+		return units.Byte
+	case Unit_UNIT_CELSIUS:
+		return units.Celsius
+	case Unit_UNIT_PERCENT_BANDWIDTH:
+		return units.PercentBandwidth
+	case Unit_UNIT_16THS_OF_IEC_BYTE:
+		return units.Bit16th
+	default:
+		return units.None
+	}
+}
+
+func timestampToProto(ts interface{}) *timestamppb.Timestamp {
+	t, ok := ts.(time.Time)
+	if !ok || t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func timestampFromProto(ts *timestamppb.Timestamp) interface{} {
+	if ts == nil {
+		return nil
+	}
+	return ts.AsTime()
+}
@@ -0,0 +1,10 @@
+// Package messagespb provides the protobuf/gRPC counterpart to the
+// go/tricorder/messages package, along with helpers to convert between the
+// two. The generated types (Metric, Distribution, MetricServiceServer,
+// etc.) live in metric.pb.go and metric_grpc.pb.go, produced from
+// metric.proto by the directive below; run `make generate` (or the
+// go:generate directive directly) after changing metric.proto, before
+// building this package or grpcserver.
+package messagespb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative metric.proto
@@ -0,0 +1,87 @@
+// Package otlpexporter periodically walks a tricorder metrics tree and
+// pushes the results to an OpenTelemetry Protocol (OTLP) metrics endpoint,
+// either over gRPC or over HTTP/protobuf.
+package otlpexporter
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/messages"
+)
+
+// MetricSource enumerates the metrics that should be exported. The value
+// registered with tricorder.RegisterMetric satisfies this interface.
+type MetricSource interface {
+	// ListMetrics returns every currently registered metric.
+	ListMetrics() (messages.MetricList, error)
+}
+
+// Protocol selects the OTLP wire protocol used to reach the collector.
+type Protocol int
+
+const (
+	// ProtocolGRPC sends OTLP ExportMetricsServiceRequest messages over
+	// gRPC. This is the default.
+	ProtocolGRPC Protocol = iota
+	// ProtocolHTTP sends OTLP ExportMetricsServiceRequest messages as
+	// protobuf-encoded HTTP POST bodies.
+	ProtocolHTTP
+)
+
+// Config configures an Exporter.
+type Config struct {
+	// Source provides the metrics to export. Required.
+	Source MetricSource
+	// Endpoint is the OTLP collector address, e.g. "localhost:4317" for
+	// gRPC or "https://localhost:4318/v1/metrics" for HTTP. Required.
+	Endpoint string
+	// Protocol selects gRPC or HTTP/protobuf. Defaults to ProtocolGRPC.
+	Protocol Protocol
+	// Interval is how often metrics are pushed. Required.
+	Interval time.Duration
+	// TLSConfig configures transport security. A nil value uses plaintext
+	// for ProtocolGRPC dial options and the platform default for
+	// ProtocolHTTP.
+	TLSConfig *tls.Config
+	// Headers are attached to every export request, e.g. for
+	// "Authorization" or multi-tenant "X-Scope-OrgID" headers.
+	Headers map[string]string
+	// ResourceAttributes identify the process emitting these metrics,
+	// e.g. "service.name", "service.instance.id".
+	ResourceAttributes map[string]string
+}
+
+// Exporter periodically pushes metrics from a Config.Source to an OTLP
+// endpoint.
+type Exporter struct {
+	*exporter
+}
+
+// New creates an Exporter from cfg. It does not start exporting until
+// Start is called.
+func New(cfg Config) (*Exporter, error) {
+	e, err := newExporter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Exporter{e}, nil
+}
+
+// Start begins the periodic export loop in a new goroutine. Start must not
+// be called more than once.
+func (e *Exporter) Start() {
+	e.start()
+}
+
+// Stop halts the export loop and closes the underlying connection. It is
+// safe to call Stop more than once.
+func (e *Exporter) Stop() error {
+	return e.stop()
+}
+
+// ExportOnce performs a single export outside of the periodic loop,
+// returning any error encountered.
+func (e *Exporter) ExportOnce() error {
+	return e.exportOnce()
+}
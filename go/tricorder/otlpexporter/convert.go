@@ -0,0 +1,144 @@
+package otlpexporter
+
+import (
+	"fmt"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/messages"
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/types"
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/units"
+)
+
+// convertMetrics converts a tricorder metrics list into OTLP Metric
+// messages, one per tricorder metric (types.List metrics become one Gauge
+// with one data point per element). Metrics whose value OTLP has no
+// numeric representation for (types.String, types.Time, types.Duration)
+// are skipped rather than failing the whole export.
+func convertMetrics(list messages.MetricList) ([]*metricpb.Metric, error) {
+	result := make([]*metricpb.Metric, 0, len(list))
+	for _, m := range list {
+		converted, err := convertMetric(m)
+		if err != nil {
+			return nil, fmt.Errorf("otlpexporter: %s: %w", m.Path, err)
+		}
+		if converted != nil {
+			result = append(result, converted)
+		}
+	}
+	return result, nil
+}
+
+func convertMetric(m *messages.Metric) (*metricpb.Metric, error) {
+	out := &metricpb.Metric{
+		Name:        m.Path,
+		Description: m.Description,
+		Unit:        otlpUnit(m.Unit),
+	}
+	nanos := timestampNanos(m)
+	switch m.Kind {
+	case types.Dist:
+		dist, ok := m.Value.(*messages.Distribution)
+		if !ok {
+			return nil, fmt.Errorf("expected *messages.Distribution, got %T", m.Value)
+		}
+		out.Data = &metricpb.Metric_Histogram{Histogram: convertHistogram(dist, nanos)}
+	case types.List:
+		values, err := listValues(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		out.Data = &metricpb.Metric_Gauge{Gauge: &metricpb.Gauge{
+			DataPoints: listDataPoints(values, nanos),
+		}}
+	default:
+		value, err := scalarValue(m.Value)
+		if err != nil {
+			return nil, nil
+		}
+		point := &metricpb.NumberDataPoint{
+			TimeUnixNano: nanos,
+			Value:        &metricpb.NumberDataPoint_AsDouble{AsDouble: value},
+		}
+		// tricorder does not distinguish counters from gauges for
+		// scalar metrics, so every scalar becomes a Gauge; only
+		// messages.Distribution carries IsNotCumulative, which
+		// selects the Histogram's aggregation temporality below.
+		out.Data = &metricpb.Metric_Gauge{Gauge: &metricpb.Gauge{
+			DataPoints: []*metricpb.NumberDataPoint{point},
+		}}
+	}
+	return out, nil
+}
+
+// convertHistogram converts a tricorder Distribution into an OTLP
+// Histogram data point, mapping Ranges directly to explicit bucket
+// boundaries and counts. The highest range never has an Upper bound (see
+// RangeWithCount), so only the finite ranges contribute an explicit
+// bound; the highest range's count becomes the implicit final bucket
+// OTLP's BucketCounts expects (one more entry than ExplicitBounds).
+// IsNotCumulative selects the aggregation temporality.
+func convertHistogram(d *messages.Distribution, nanos uint64) *metricpb.Histogram {
+	bounds := make([]float64, 0, len(d.Ranges))
+	counts := make([]uint64, 0, len(d.Ranges))
+	if len(d.Ranges) > 0 {
+		finite := d.Ranges[:len(d.Ranges)-1]
+		for _, r := range finite {
+			bounds = append(bounds, r.Upper)
+			counts = append(counts, r.Count)
+		}
+		counts = append(counts, d.Ranges[len(d.Ranges)-1].Count)
+	}
+	temporality := metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+	if d.IsNotCumulative {
+		temporality = metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA
+	}
+	min, max := d.Min, d.Max
+	return &metricpb.Histogram{
+		AggregationTemporality: temporality,
+		DataPoints: []*metricpb.HistogramDataPoint{{
+			TimeUnixNano:   nanos,
+			Count:          d.Count,
+			Sum:            &d.Sum,
+			Min:            &min,
+			Max:            &max,
+			ExplicitBounds: bounds,
+			BucketCounts:   counts,
+		}},
+	}
+}
+
+func listDataPoints(values []float64, nanos uint64) []*metricpb.NumberDataPoint {
+	points := make([]*metricpb.NumberDataPoint, len(values))
+	for i, v := range values {
+		points[i] = &metricpb.NumberDataPoint{
+			TimeUnixNano: nanos,
+			Value:        &metricpb.NumberDataPoint_AsDouble{AsDouble: v},
+			Attributes: []*commonpb.KeyValue{{
+				Key:   "index",
+				Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(i)}},
+			}},
+		}
+	}
+	return points
+}
+
+// otlpUnit maps a tricorder units.Unit to the unit string OTLP
+// conventionally uses.
+func otlpUnit(u units.Unit) string {
+	if u == 0 {
+		return ""
+	}
+	return u.String()
+}
+
+func timestampNanos(m *messages.Metric) uint64 {
+	switch ts := m.TimeStamp.(type) {
+	case time.Time:
+		return uint64(ts.UnixNano())
+	default:
+		return 0
+	}
+}
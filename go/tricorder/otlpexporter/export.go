@@ -0,0 +1,183 @@
+package otlpexporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+// exporter is the unexported implementation behind Exporter.
+type exporter struct {
+	cfg      Config
+	resource *resourcepb.Resource
+	client   *http.Client
+
+	grpcConn   *grpc.ClientConn
+	grpcClient collectorpb.MetricsServiceClient
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+func newExporter(cfg Config) (*exporter, error) {
+	if cfg.Source == nil {
+		return nil, fmt.Errorf("otlpexporter: Source is required")
+	}
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("otlpexporter: Endpoint is required")
+	}
+	if cfg.Interval <= 0 {
+		return nil, fmt.Errorf("otlpexporter: Interval must be positive")
+	}
+	e := &exporter{
+		cfg:      cfg,
+		resource: resourceFromAttributes(cfg.ResourceAttributes),
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+	if cfg.Protocol == ProtocolGRPC {
+		creds := insecure.NewCredentials()
+		if cfg.TLSConfig != nil {
+			creds = credentials.NewTLS(cfg.TLSConfig)
+		}
+		conn, err := grpc.NewClient(cfg.Endpoint, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, fmt.Errorf("otlpexporter: dialing %s: %w", cfg.Endpoint, err)
+		}
+		e.grpcConn = conn
+		e.grpcClient = collectorpb.NewMetricsServiceClient(conn)
+	} else {
+		e.client = &http.Client{Timeout: 30 * time.Second}
+		if cfg.TLSConfig != nil {
+			e.client.Transport = &http.Transport{TLSClientConfig: cfg.TLSConfig}
+		}
+	}
+	return e, nil
+}
+
+func resourceFromAttributes(attrs map[string]string) *resourcepb.Resource {
+	if len(attrs) == 0 {
+		return &resourcepb.Resource{}
+	}
+	kvs := make([]*commonpb.KeyValue, 0, len(attrs))
+	for k, v := range attrs {
+		kvs = append(kvs, &commonpb.KeyValue{
+			Key:   k,
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}},
+		})
+	}
+	return &resourcepb.Resource{Attributes: kvs}
+}
+
+func (e *exporter) start() {
+	go e.loop()
+}
+
+func (e *exporter) stop() error {
+	var err error
+	e.stopOnce.Do(func() {
+		close(e.stopCh)
+		<-e.doneCh
+		if e.grpcConn != nil {
+			err = e.grpcConn.Close()
+		}
+	})
+	return err
+}
+
+func (e *exporter) loop() {
+	defer close(e.doneCh)
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stopCh:
+			return
+		case <-ticker.C:
+			if err := e.exportOnce(); err != nil {
+				log.Printf("otlpexporter: export to %s failed: %v", e.cfg.Endpoint, err)
+			}
+		}
+	}
+}
+
+func (e *exporter) exportOnce() error {
+	metrics, err := e.cfg.Source.ListMetrics()
+	if err != nil {
+		return fmt.Errorf("otlpexporter: listing metrics: %w", err)
+	}
+	otlpMetrics, err := convertMetrics(metrics)
+	if err != nil {
+		return err
+	}
+	req := &collectorpb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricpb.ResourceMetrics{{
+			Resource: e.resource,
+			ScopeMetrics: []*metricpb.ScopeMetrics{{
+				Metrics: otlpMetrics,
+			}},
+		}},
+	}
+	if e.cfg.Protocol == ProtocolGRPC {
+		return e.exportGRPC(req)
+	}
+	return e.exportHTTP(req)
+}
+
+func (e *exporter) exportGRPC(req *collectorpb.ExportMetricsServiceRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	ctx = withHeaders(ctx, e.cfg.Headers)
+	_, err := e.grpcClient.Export(ctx, req)
+	return err
+}
+
+func (e *exporter) exportHTTP(req *collectorpb.ExportMetricsServiceRequest) error {
+	body, err := proto.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("otlpexporter: marshaling request: %w", err)
+	}
+	httpReq, err := http.NewRequest(http.MethodPost, e.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("otlpexporter: building request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range e.cfg.Headers {
+		httpReq.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("otlpexporter: posting to %s: %w", e.cfg.Endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("otlpexporter: %s returned status %s: %s", e.cfg.Endpoint, resp.Status, respBody)
+	}
+	return nil
+}
+
+func withHeaders(ctx context.Context, headers map[string]string) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	md := metadata.New(headers)
+	return metadata.NewOutgoingContext(ctx, md)
+}
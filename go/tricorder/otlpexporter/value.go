@@ -0,0 +1,58 @@
+package otlpexporter
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// scalarValue converts a tricorder scalar metric value to float64, the
+// only numeric representation OTLP's NumberDataPoint uses here.
+func scalarValue(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case int8:
+		return float64(v), nil
+	case int16:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint8:
+		return float64(v), nil
+	case uint16:
+		return float64(v), nil
+	case uint32:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	default:
+		return 0, fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+// listValues converts a tricorder types.List value to a slice of float64,
+// one per element, preserving order.
+func listValues(value interface{}) ([]float64, error) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("unsupported list type %T", value)
+	}
+	result := make([]float64, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		v, err := scalarValue(rv.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		result[i] = v
+	}
+	return result, nil
+}
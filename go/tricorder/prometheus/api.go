@@ -0,0 +1,68 @@
+// Package prometheus exposes tricorder metrics in the Prometheus text
+// exposition format and pushes them to a remote_write endpoint.
+//
+// Callers that already serve tricorder metrics over HTTP can register the
+// handler returned by NewHandler alongside their existing /metrics,
+// /healthz, etc. endpoints. Callers that instead need to push metrics to a
+// Prometheus remote_write receiver (e.g. Cortex, Thanos, Mimir) can use
+// Pusher.
+package prometheus
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/messages"
+)
+
+// MetricSource enumerates the metrics that should be exported. The value
+// registered with tricorder.RegisterMetric satisfies this interface.
+type MetricSource interface {
+	// ListMetrics returns every currently registered metric.
+	ListMetrics() (messages.MetricList, error)
+}
+
+// Options configures a Handler or a Pusher.
+type Options struct {
+	// Source provides the metrics to export. Required.
+	Source MetricSource
+	// Namespace, if non-empty, is prepended to every metric name as
+	// "<Namespace>_".
+	Namespace string
+}
+
+// NewHandler returns an http.Handler that serves opts.Source's metrics in
+// the Prometheus text exposition format. The handler is suitable for
+// registering at the conventional "/metrics" path.
+func NewHandler(opts Options) http.Handler {
+	return &handler{opts: opts}
+}
+
+// Pusher periodically serializes metrics as a Prometheus remote_write
+// WriteRequest and pushes it to a remote_write endpoint.
+type Pusher struct {
+	*pusher
+}
+
+// NewPusher creates a Pusher that pushes opts.Source's metrics to url every
+// interval. Call Start to begin pushing.
+func NewPusher(url string, interval time.Duration, opts Options) *Pusher {
+	return &Pusher{newPusher(url, interval, opts)}
+}
+
+// Start begins the periodic push loop in a new goroutine. Start must not be
+// called more than once.
+func (p *Pusher) Start() {
+	p.start()
+}
+
+// Stop halts the push loop. It is safe to call Stop more than once.
+func (p *Pusher) Stop() {
+	p.stop()
+}
+
+// PushOnce performs a single push to the remote_write endpoint outside of
+// the periodic loop, returning any error encountered.
+func (p *Pusher) PushOnce() error {
+	return p.pushOnce()
+}
@@ -0,0 +1,189 @@
+package prometheus
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/messages"
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/types"
+)
+
+// sample is a single Prometheus time series: a metric name, its labels, and
+// one float64 value observed at timestampMillis.
+type sample struct {
+	name            string
+	labels          map[string]string
+	value           float64
+	timestampMillis int64
+}
+
+// metricFamily is a sample's static metadata: its HELP text and Prometheus
+// metric type (gauge, counter, histogram).
+type metricFamily struct {
+	name     string
+	help     string
+	promType string
+	samples  []sample
+}
+
+// convertMetric turns a single tricorder metric into one or more Prometheus
+// metric families. types.List metrics become one family with one sample
+// per element, each with an "index" label; messages.Distribution metrics become a histogram
+// family. Metrics whose value Prometheus has no numeric representation
+// for (types.String, types.Time, types.Duration) are skipped: convertMetric
+// returns (nil, nil) rather than failing the whole scrape.
+func convertMetric(namespace string, m *messages.Metric) ([]metricFamily, error) {
+	name := metricName(namespace, m.Path)
+	ts := timestampMillis(m)
+	if m.Kind == types.List {
+		return convertList(name, m, ts)
+	}
+	if m.Kind == types.Dist {
+		dist, ok := m.Value.(*messages.Distribution)
+		if !ok {
+			return nil, fmt.Errorf("prometheus: %s: expected *messages.Distribution, got %T", m.Path, m.Value)
+		}
+		return []metricFamily{convertDistribution(name, m, dist, ts)}, nil
+	}
+	value, err := scalarValue(m.Value)
+	if err != nil {
+		return nil, nil
+	}
+	return []metricFamily{{
+		name:     name,
+		help:     helpText(m),
+		promType: promTypeOf(m),
+		samples: []sample{{
+			name:            name,
+			value:           value,
+			timestampMillis: ts,
+		}},
+	}}, nil
+}
+
+// convertList flattens a types.List metric into a single family with one
+// sample per element, each sample labeled with its zero-based index.
+func convertList(name string, m *messages.Metric, ts int64) ([]metricFamily, error) {
+	values, err := listValues(m.Value)
+	if err != nil {
+		return nil, fmt.Errorf("prometheus: %s: %w", m.Path, err)
+	}
+	family := metricFamily{
+		name:     name,
+		help:     helpText(m),
+		promType: "gauge",
+	}
+	for i, v := range values {
+		family.samples = append(family.samples, sample{
+			name:            name,
+			labels:          map[string]string{"index": strconv.Itoa(i)},
+			value:           v,
+			timestampMillis: ts,
+		})
+	}
+	return []metricFamily{family}, nil
+}
+
+// convertDistribution converts a messages.Distribution into a Prometheus
+// histogram: one sample per cumulative bucket ("le" label), plus the
+// conventional _sum and _count samples. The highest range never has an
+// Upper bound (see RangeWithCount), so only the finite ranges get an "le"
+// bucket; the highest range's count is folded into the conventional
+// "le=+Inf" bucket alongside everything below it.
+func convertDistribution(name string, m *messages.Metric, d *messages.Distribution, ts int64) metricFamily {
+	bucketName := name + "_bucket"
+	family := metricFamily{
+		name:     name,
+		help:     helpText(m),
+		promType: "histogram",
+	}
+	var cumulative uint64
+	if len(d.Ranges) > 0 {
+		for _, r := range d.Ranges[:len(d.Ranges)-1] {
+			cumulative += r.Count
+			family.samples = append(family.samples, sample{
+				name:            bucketName,
+				labels:          map[string]string{"le": formatBound(r.Upper)},
+				value:           float64(cumulative),
+				timestampMillis: ts,
+			})
+		}
+	}
+	family.samples = append(family.samples,
+		sample{
+			name:            bucketName,
+			labels:          map[string]string{"le": "+Inf"},
+			value:           float64(d.Count),
+			timestampMillis: ts,
+		},
+		sample{name: name + "_sum", value: d.Sum, timestampMillis: ts},
+		sample{name: name + "_count", value: float64(d.Count), timestampMillis: ts},
+	)
+	return family
+}
+
+// metricName builds a Prometheus-legal metric name from a tricorder metric
+// path by trimming leading and trailing "/", replacing every character
+// other than an ASCII letter or digit (including "/") with "_", and
+// prefixing the namespace, if any.
+func metricName(namespace, path string) string {
+	name := strings.Trim(path, "/")
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	if namespace != "" {
+		name = namespace + "_" + name
+	}
+	return name
+}
+
+// promTypeOf reports the Prometheus metric type for m. tricorder does not
+// distinguish counters from gauges for scalar metrics, so all scalars are
+// exported as gauges; only messages.Distribution gets a more specific type.
+func promTypeOf(m *messages.Metric) string {
+	return "gauge"
+}
+
+// helpText builds the HELP line for m, folding in its unit of measurement
+// when one is set.
+func helpText(m *messages.Metric) string {
+	if m.Unit == 0 {
+		return m.Description
+	}
+	if m.Description == "" {
+		return fmt.Sprintf("Unit: %s.", m.Unit)
+	}
+	return fmt.Sprintf("%s Unit: %s.", m.Description, m.Unit)
+}
+
+// formatBound formats a histogram bucket's upper bound the way Prometheus
+// clients conventionally do.
+func formatBound(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// timestampMillis extracts the metric's timestamp in Unix milliseconds, or
+// 0 if it is unset/unrecognized.
+func timestampMillis(m *messages.Metric) int64 {
+	switch ts := m.TimeStamp.(type) {
+	case nil:
+		return 0
+	case time.Time:
+		return ts.UnixNano() / int64(time.Millisecond)
+	case string:
+		f, err := strconv.ParseFloat(ts, 64)
+		if err != nil || ts == "" {
+			return 0
+		}
+		return int64(f * 1000)
+	default:
+		return 0
+	}
+}
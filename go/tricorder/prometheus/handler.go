@@ -0,0 +1,98 @@
+package prometheus
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+)
+
+// handler implements http.Handler, serving opts.Source's metrics in the
+// Prometheus text exposition format.
+type handler struct {
+	opts Options
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	families, err := gatherFamilies(h.opts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	for _, family := range families {
+		writeFamily(bw, family)
+	}
+	bw.Flush()
+}
+
+// gatherFamilies lists opts.Source's metrics and converts each one to one
+// or more Prometheus metric families.
+func gatherFamilies(opts Options) ([]metricFamily, error) {
+	metrics, err := opts.Source.ListMetrics()
+	if err != nil {
+		return nil, fmt.Errorf("prometheus: listing metrics: %w", err)
+	}
+	var families []metricFamily
+	for _, m := range metrics {
+		converted, err := convertMetric(opts.Namespace, m)
+		if err != nil {
+			return nil, err
+		}
+		families = append(families, converted...)
+	}
+	return families, nil
+}
+
+// writeFamily writes a single metric family's HELP/TYPE header and samples
+// in the Prometheus text exposition format.
+func writeFamily(bw *bufio.Writer, family metricFamily) {
+	if family.help != "" {
+		fmt.Fprintf(bw, "# HELP %s %s\n", family.name, escapeHelp(family.help))
+	}
+	fmt.Fprintf(bw, "# TYPE %s %s\n", family.name, family.promType)
+	for _, s := range family.samples {
+		fmt.Fprint(bw, s.name)
+		writeLabels(bw, s.labels)
+		fmt.Fprintf(bw, " %s", formatValue(s.value))
+		if s.timestampMillis != 0 {
+			fmt.Fprintf(bw, " %d", s.timestampMillis)
+		}
+		fmt.Fprint(bw, "\n")
+	}
+}
+
+func writeLabels(bw *bufio.Writer, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	fmt.Fprint(bw, "{")
+	first := true
+	for name, value := range labels {
+		if !first {
+			fmt.Fprint(bw, ",")
+		}
+		first = false
+		fmt.Fprintf(bw, "%s=%q", name, value)
+	}
+	fmt.Fprint(bw, "}")
+}
+
+func escapeHelp(s string) string {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			out = append(out, '\\', '\\')
+		case '\n':
+			out = append(out, '\\', 'n')
+		default:
+			out = append(out, s[i])
+		}
+	}
+	return string(out)
+}
+
+func formatValue(v float64) string {
+	return formatBound(v)
+}
@@ -0,0 +1,127 @@
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"google.golang.org/protobuf/proto"
+)
+
+// pusher is the unexported implementation behind Pusher.
+type pusher struct {
+	url      string
+	interval time.Duration
+	opts     Options
+	client   *http.Client
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+func newPusher(url string, interval time.Duration, opts Options) *pusher {
+	return &pusher{
+		url:      url,
+		interval: interval,
+		opts:     opts,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+func (p *pusher) start() {
+	go p.loop()
+}
+
+func (p *pusher) stop() {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+		<-p.doneCh
+	})
+}
+
+func (p *pusher) loop() {
+	defer close(p.doneCh)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			if err := p.pushOnce(); err != nil {
+				log.Printf("prometheus: push to %s failed: %v", p.url, err)
+			}
+		}
+	}
+}
+
+func (p *pusher) pushOnce() error {
+	families, err := gatherFamilies(p.opts)
+	if err != nil {
+		return err
+	}
+	req := &prompb.WriteRequest{TimeSeries: toTimeSeries(families, time.Now())}
+	body, err := proto.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("prometheus: marshaling WriteRequest: %w", err)
+	}
+	compressed := snappy.Encode(nil, body)
+	httpReq, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(compressed))
+	if err != nil {
+		return fmt.Errorf("prometheus: building request: %w", err)
+	}
+	httpReq.Header.Set("Content-Encoding", "snappy")
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	httpReq.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("prometheus: pushing to %s: %w", p.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("prometheus: %s returned status %s", p.url, resp.Status)
+	}
+	return nil
+}
+
+// toTimeSeries flattens metric families into remote_write TimeSeries, one
+// per distinct sample (name + label set). A sample with no timestamp (its
+// metric's TimeStamp was unset or unrecognized; see timestampMillis) gets
+// pushTime instead of the epoch: remote_write receivers reject samples
+// timestamped at 0 as out of bounds, whereas text exposition simply omits
+// the timestamp, so 0 is only safe there.
+func toTimeSeries(families []metricFamily, pushTime time.Time) []prompb.TimeSeries {
+	pushMillis := pushTime.UnixNano() / int64(time.Millisecond)
+	var series []prompb.TimeSeries
+	for _, family := range families {
+		for _, s := range family.samples {
+			labels := make([]prompb.Label, 0, len(s.labels)+1)
+			labels = append(labels, prompb.Label{Name: "__name__", Value: s.name})
+			for name, value := range s.labels {
+				labels = append(labels, prompb.Label{Name: name, Value: value})
+			}
+			sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+			timestampMillis := s.timestampMillis
+			if timestampMillis == 0 {
+				timestampMillis = pushMillis
+			}
+			series = append(series, prompb.TimeSeries{
+				Labels: labels,
+				Samples: []prompb.Sample{{
+					Value:     s.value,
+					Timestamp: timestampMillis,
+				}},
+			})
+		}
+	}
+	return series
+}
@@ -0,0 +1,76 @@
+// Package stackdriver translates a tricorder metrics tree into Google
+// Cloud Monitoring (formerly Stackdriver) MetricDescriptors and periodically
+// writes TimeSeries for them via the Cloud Monitoring v3 API.
+package stackdriver
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/api/option"
+
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/messages"
+)
+
+// MetricSource enumerates the metrics that should be exported. The value
+// registered with tricorder.RegisterMetric satisfies this interface.
+type MetricSource interface {
+	// ListMetrics returns every currently registered metric.
+	ListMetrics() (messages.MetricList, error)
+}
+
+// Config configures an Exporter.
+type Config struct {
+	// Source provides the metrics to export. Required.
+	Source MetricSource
+	// ProjectID is the GCP project metrics are written to, e.g.
+	// "my-project". Required.
+	ProjectID string
+	// Prefix is prepended to each metric's type, forming
+	// "custom.googleapis.com/<Prefix>/<path>", with surrounding "/"
+	// trimmed from both and path sanitized as described at metricType.
+	// Defaults to "" (resulting in a type of
+	// "custom.googleapis.com/<path>").
+	Prefix string
+	// Interval is how often metrics are pushed. Required.
+	Interval time.Duration
+	// ClientOptions configures authentication and transport for the
+	// underlying Cloud Monitoring client, e.g.
+	// option.WithCredentialsFile("service-account.json"). A nil slice
+	// uses Application Default Credentials.
+	ClientOptions []option.ClientOption
+}
+
+// Exporter periodically creates MetricDescriptors and writes TimeSeries to
+// Cloud Monitoring for a Config.Source.
+type Exporter struct {
+	*exporter
+}
+
+// New creates an Exporter from cfg. It does not start exporting until
+// Start is called.
+func New(ctx context.Context, cfg Config) (*Exporter, error) {
+	e, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Exporter{e}, nil
+}
+
+// Start begins the periodic export loop in a new goroutine. Start must not
+// be called more than once.
+func (e *Exporter) Start() {
+	e.start()
+}
+
+// Stop halts the export loop and closes the underlying client. It is safe
+// to call Stop more than once.
+func (e *Exporter) Stop() error {
+	return e.stop()
+}
+
+// PushOnce performs a single descriptor sync and TimeSeries write outside
+// of the periodic loop, returning any error encountered.
+func (e *Exporter) PushOnce(ctx context.Context) error {
+	return e.pushOnce(ctx)
+}
@@ -0,0 +1,224 @@
+package stackdriver
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/api/distribution"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	"google.golang.org/genproto/googleapis/api/monitoredres"
+	monitoringpb "google.golang.org/genproto/googleapis/monitoring/v3"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/messages"
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/types"
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/units"
+)
+
+// metricType builds the "custom.googleapis.com/..." type for m's path.
+// Cloud Monitoring custom metric types only allow [A-Za-z0-9_/] after the
+// domain, so the "/"-separated path is kept but any other character
+// (notably the hyphens tricorder paths use, e.g. "/proc/cpu/user-time")
+// is replaced with "_"; path is also trimmed of its leading "/" so it
+// doesn't produce an empty first segment when joined with prefix.
+func metricType(prefix, path string) string {
+	suffix := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '/':
+			return r
+		default:
+			return '_'
+		}
+	}, strings.Trim(path, "/"))
+	prefix = strings.Trim(prefix, "/")
+	if prefix != "" {
+		suffix = prefix + "/" + suffix
+	}
+	return "custom.googleapis.com/" + suffix
+}
+
+// descriptorFor builds the MetricDescriptor for m. Its MetricKind comes
+// from whether the metric is cumulative, ValueType from its Kind/SubType,
+// and Unit from units.Unit.
+func descriptorFor(projectID, prefix string, m *messages.Metric) (*metricpb.MetricDescriptor, error) {
+	valueType, err := valueTypeOf(m)
+	if err != nil {
+		return nil, err
+	}
+	return &metricpb.MetricDescriptor{
+		Name:        fmt.Sprintf("projects/%s/metricDescriptors/%s", projectID, metricType(prefix, m.Path)),
+		Type:        metricType(prefix, m.Path),
+		MetricKind:  metricKindOf(m),
+		ValueType:   valueType,
+		Unit:        unitOf(m.Unit),
+		Description: m.Description,
+	}, nil
+}
+
+// metricKindOf chooses GAUGE or CUMULATIVE for m based on IsNotCumulative.
+// Only messages.Distribution carries that field; all other metric kinds
+// are reported as GAUGE.
+func metricKindOf(m *messages.Metric) metricpb.MetricDescriptor_MetricKind {
+	if dist, ok := m.Value.(*messages.Distribution); ok && !dist.IsNotCumulative {
+		return metricpb.MetricDescriptor_CUMULATIVE
+	}
+	return metricpb.MetricDescriptor_GAUGE
+}
+
+// valueTypeOf derives the Cloud Monitoring ValueType from m's Kind and,
+// for lists, SubType. types.String/Time/Duration have no numeric
+// representation Cloud Monitoring TimeSeries will accept (it rejects
+// STRING-typed custom metric writes), so, like the otlp and prometheus
+// exporters, valueTypeOf reports them as unsupported rather than mapping
+// them to MetricDescriptor_STRING; callers already skip a metric whose
+// valueTypeOf fails instead of failing the whole push.
+func valueTypeOf(m *messages.Metric) (metricpb.MetricDescriptor_ValueType, error) {
+	kind := m.Kind
+	if kind == types.List {
+		kind = m.SubType
+	}
+	switch kind {
+	case types.Bool:
+		return metricpb.MetricDescriptor_BOOL, nil
+	case types.Dist:
+		return metricpb.MetricDescriptor_DISTRIBUTION, nil
+	case types.Int8, types.Int16, types.Int32, types.Int64,
+		types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+		return metricpb.MetricDescriptor_INT64, nil
+	case types.Float32, types.Float64:
+		return metricpb.MetricDescriptor_DOUBLE, nil
+	default:
+		return 0, fmt.Errorf("stackdriver: %s: unsupported kind %v", m.Path, kind)
+	}
+}
+
+// unitOf maps a tricorder units.Unit to a Cloud Monitoring unit string.
+func unitOf(u units.Unit) string {
+	if u == 0 {
+		return "1"
+	}
+	return u.String()
+}
+
+// pointValue builds the TypedValue for a single, non-Distribution metric.
+func pointValue(m *messages.Metric) (*monitoringpb.TypedValue, error) {
+	valueType, err := valueTypeOf(m)
+	if err != nil {
+		return nil, err
+	}
+	switch valueType {
+	case metricpb.MetricDescriptor_BOOL:
+		return &monitoringpb.TypedValue{Value: &monitoringpb.TypedValue_BoolValue{
+			BoolValue: m.Value.(bool),
+		}}, nil
+	case metricpb.MetricDescriptor_INT64:
+		v, err := toInt64(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &monitoringpb.TypedValue{Value: &monitoringpb.TypedValue_Int64Value{Int64Value: v}}, nil
+	case metricpb.MetricDescriptor_DOUBLE:
+		v, err := toFloat64(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &monitoringpb.TypedValue{Value: &monitoringpb.TypedValue_DoubleValue{DoubleValue: v}}, nil
+	default:
+		return nil, fmt.Errorf("stackdriver: %s: unsupported value type %v", m.Path, valueType)
+	}
+}
+
+// distributionValue translates a tricorder Distribution into a Cloud
+// Monitoring Distribution value, building explicit bucket bounds from
+// Ranges. The highest range never has an Upper bound (see
+// RangeWithCount), so only the finite ranges contribute a bound; the
+// highest range's Count becomes the implicit overflow bucket Cloud
+// Monitoring expects (one more count than bounds).
+func distributionValue(d *messages.Distribution) *monitoringpb.TypedValue {
+	bounds := make([]float64, 0, len(d.Ranges))
+	counts := make([]int64, 0, len(d.Ranges))
+	if len(d.Ranges) > 0 {
+		finite := d.Ranges[:len(d.Ranges)-1]
+		for _, r := range finite {
+			bounds = append(bounds, r.Upper)
+			counts = append(counts, int64(r.Count))
+		}
+		counts = append(counts, int64(d.Ranges[len(d.Ranges)-1].Count))
+	}
+	return &monitoringpb.TypedValue{Value: &monitoringpb.TypedValue_DistributionValue{
+		DistributionValue: &distribution.Distribution{
+			Count: int64(d.Count),
+			Mean:  d.Average,
+			BucketOptions: &distribution.Distribution_BucketOptions{
+				Options: &distribution.Distribution_BucketOptions_ExplicitBuckets{
+					ExplicitBuckets: &distribution.Distribution_BucketOptions_Explicit{
+						Bounds: bounds,
+					},
+				},
+			},
+			BucketCounts: counts,
+		},
+	}}
+}
+
+func toInt64(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint8:
+		return int64(v), nil
+	case uint16:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case uint64:
+		return int64(v), nil
+	default:
+		return 0, fmt.Errorf("unsupported int type %T", value)
+	}
+}
+
+func toFloat64(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	default:
+		return 0, fmt.Errorf("unsupported float type %T", value)
+	}
+}
+
+// interval builds a TimeInterval for a single point in time, as required
+// for GAUGE metrics.
+func interval(t time.Time) *monitoringpb.TimeInterval {
+	return &monitoringpb.TimeInterval{EndTime: timestamppb.New(t)}
+}
+
+// cumulativeInterval builds a TimeInterval for a CUMULATIVE metric, which
+// the Cloud Monitoring API rejects unless StartTime is strictly before
+// EndTime.
+func cumulativeInterval(start, end time.Time) *monitoringpb.TimeInterval {
+	return &monitoringpb.TimeInterval{
+		StartTime: timestamppb.New(start),
+		EndTime:   timestamppb.New(end),
+	}
+}
+
+// monitoredResource is the "global" resource type, suitable for custom
+// metrics that are not tied to a specific GCP resource.
+func monitoredResource(projectID string) *monitoredres.MonitoredResource {
+	return &monitoredres.MonitoredResource{
+		Type: "global",
+		Labels: map[string]string{
+			"project_id": projectID,
+		},
+	}
+}
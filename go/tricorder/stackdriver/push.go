@@ -0,0 +1,238 @@
+package stackdriver
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	monitoring "cloud.google.com/go/monitoring/apiv3/v2"
+	"google.golang.org/api/option"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	monitoringpb "google.golang.org/genproto/googleapis/monitoring/v3"
+
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/messages"
+	"github.com/Cloud-Foundations/tricorder/go/tricorder/types"
+)
+
+// maxPointsPerRequest is the Cloud Monitoring API's limit on the number of
+// TimeSeries a single CreateTimeSeriesRequest may contain.
+const maxPointsPerRequest = 200
+
+// exporter is the unexported implementation behind Exporter.
+type exporter struct {
+	cfg    Config
+	client *monitoring.MetricClient
+
+	knownDescriptors sync.Map // metric type -> struct{}
+	startTimes       sync.Map // metric type -> start time.Time, for CUMULATIVE points
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+func newExporter(ctx context.Context, cfg Config) (*exporter, error) {
+	if cfg.Source == nil {
+		return nil, fmt.Errorf("stackdriver: Source is required")
+	}
+	if cfg.ProjectID == "" {
+		return nil, fmt.Errorf("stackdriver: ProjectID is required")
+	}
+	if cfg.Interval <= 0 {
+		return nil, fmt.Errorf("stackdriver: Interval must be positive")
+	}
+	opts := cfg.ClientOptions
+	if len(opts) == 0 {
+		opts = []option.ClientOption{}
+	}
+	client, err := monitoring.NewMetricClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("stackdriver: creating monitoring client: %w", err)
+	}
+	return &exporter{
+		cfg:    cfg,
+		client: client,
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}, nil
+}
+
+func (e *exporter) start() {
+	go e.loop()
+}
+
+func (e *exporter) stop() error {
+	var err error
+	e.stopOnce.Do(func() {
+		close(e.stopCh)
+		<-e.doneCh
+		err = e.client.Close()
+	})
+	return err
+}
+
+func (e *exporter) loop() {
+	defer close(e.doneCh)
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stopCh:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), e.cfg.Interval)
+			err := e.pushOnce(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("stackdriver: push to project %s failed: %v", e.cfg.ProjectID, err)
+			}
+		}
+	}
+}
+
+func (e *exporter) pushOnce(ctx context.Context) error {
+	metrics, err := e.cfg.Source.ListMetrics()
+	if err != nil {
+		return fmt.Errorf("stackdriver: listing metrics: %w", err)
+	}
+	if err := e.ensureDescriptors(ctx, metrics); err != nil {
+		return err
+	}
+	series, err := e.buildTimeSeries(metrics)
+	if err != nil {
+		return err
+	}
+	return e.writeTimeSeries(ctx, series)
+}
+
+// ensureDescriptors creates a MetricDescriptor for any metric whose
+// descriptor hasn't already been created by this process. Metrics whose
+// type tricorder doesn't support exporting (currently types.List; see
+// timeSeriesFor) are skipped rather than failing the whole batch.
+func (e *exporter) ensureDescriptors(ctx context.Context, metrics messages.MetricList) error {
+	for _, m := range metrics {
+		if m.Kind == types.List {
+			continue
+		}
+		mType := metricType(e.cfg.Prefix, m.Path)
+		if _, ok := e.knownDescriptors.Load(mType); ok {
+			continue
+		}
+		descriptor, err := descriptorFor(e.cfg.ProjectID, e.cfg.Prefix, m)
+		if err != nil {
+			log.Printf("stackdriver: skipping descriptor for %s: %v", m.Path, err)
+			continue
+		}
+		_, err = e.client.CreateMetricDescriptor(ctx, &monitoringpb.CreateMetricDescriptorRequest{
+			Name:             fmt.Sprintf("projects/%s", e.cfg.ProjectID),
+			MetricDescriptor: descriptor,
+		})
+		if err != nil {
+			return fmt.Errorf("stackdriver: creating descriptor for %s: %w", m.Path, err)
+		}
+		e.knownDescriptors.Store(mType, struct{}{})
+	}
+	return nil
+}
+
+// buildTimeSeries converts metrics into Cloud Monitoring TimeSeries.
+// Metrics sharing a GroupId share a timestamp, but each still becomes its
+// own TimeSeries entry. The result is sorted by GroupId, so metrics of one
+// group are adjacent; writeTimeSeries cuts fixed-size batches without
+// regard to GroupId, so a group that straddles a batch boundary is split
+// across two requests.
+func (e *exporter) buildTimeSeries(metrics messages.MetricList) ([]*monitoringpb.TimeSeries, error) {
+	sorted := make(messages.MetricList, len(metrics))
+	copy(sorted, metrics)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].GroupId < sorted[j].GroupId })
+
+	series := make([]*monitoringpb.TimeSeries, 0, len(sorted))
+	for _, m := range sorted {
+		ts, err := e.timeSeriesFor(m)
+		if err != nil {
+			log.Printf("stackdriver: skipping %s: %v", m.Path, err)
+			continue
+		}
+		if ts != nil {
+			series = append(series, ts)
+		}
+	}
+	return series, nil
+}
+
+// timeSeriesFor builds the TimeSeries for a single metric, or returns
+// (nil, nil) for metric kinds stackdriver doesn't export (currently
+// types.List, which has no single scalar value to report; flattening it
+// into per-index series, as the prometheus and otlpexporter packages do,
+// is not yet implemented here).
+func (e *exporter) timeSeriesFor(m *messages.Metric) (*monitoringpb.TimeSeries, error) {
+	if m.Kind == types.List {
+		return nil, nil
+	}
+	mType := metricType(e.cfg.Prefix, m.Path)
+	now := time.Now()
+	if t, ok := m.TimeStamp.(time.Time); ok && !t.IsZero() {
+		now = t
+	}
+	var value *monitoringpb.TypedValue
+	if dist, ok := m.Value.(*messages.Distribution); ok {
+		value = distributionValue(dist)
+	} else {
+		v, err := pointValue(m)
+		if err != nil {
+			return nil, err
+		}
+		value = v
+	}
+	kind := metricKindOf(m)
+	var pointInterval *monitoringpb.TimeInterval
+	if kind == metricpb.MetricDescriptor_CUMULATIVE {
+		pointInterval = cumulativeInterval(e.startTimeFor(mType, now), now)
+	} else {
+		pointInterval = interval(now)
+	}
+	return &monitoringpb.TimeSeries{
+		Metric: &monitoringpb.Metric{
+			Type: mType,
+		},
+		Resource:   monitoredResource(e.cfg.ProjectID),
+		MetricKind: kind,
+		Points: []*monitoringpb.Point{{
+			Interval: pointInterval,
+			Value:    value,
+		}},
+	}, nil
+}
+
+// startTimeFor returns the StartTime to use for mType's CUMULATIVE points:
+// the first time this metric type was seen, recorded as slightly before
+// end so that StartTime is always strictly less than EndTime, as Cloud
+// Monitoring requires.
+func (e *exporter) startTimeFor(mType string, end time.Time) time.Time {
+	candidate := end.Add(-time.Millisecond)
+	actual, _ := e.startTimes.LoadOrStore(mType, candidate)
+	return actual.(time.Time)
+}
+
+// writeTimeSeries sends series to Cloud Monitoring in batches honoring
+// the API's 200-point-per-request limit.
+func (e *exporter) writeTimeSeries(ctx context.Context, series []*monitoringpb.TimeSeries) error {
+	name := fmt.Sprintf("projects/%s", e.cfg.ProjectID)
+	for start := 0; start < len(series); start += maxPointsPerRequest {
+		end := start + maxPointsPerRequest
+		if end > len(series) {
+			end = len(series)
+		}
+		err := e.client.CreateTimeSeries(ctx, &monitoringpb.CreateTimeSeriesRequest{
+			Name:       name,
+			TimeSeries: series[start:end],
+		})
+		if err != nil {
+			return fmt.Errorf("stackdriver: writing time series: %w", err)
+		}
+	}
+	return nil
+}